@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/spf13/cobra"
+	"github.com/spinnaker/buildtool/pkg/bom"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+func newBomRewriteCmd() *cobra.Command {
+	var srcBucket, destBucket, prefix, jsonKeyPath string
+	var replacements []string
+
+	cmd := &cobra.Command{
+		Use:   "bom-rewrite",
+		Short: "Rewrite every BOM in --srcBucket, replacing registry references, and write the result to --destBucket.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			replace, err := parseReplacements(replacements)
+			if err != nil {
+				return err
+			}
+			return runBomRewrite(cmd.Context(), srcBucket, destBucket, prefix, jsonKeyPath, replace)
+		},
+	}
+
+	cmd.Flags().StringVar(&srcBucket, "srcBucket", "halconfig", "The GCS bucket name to read from. Must contain a bom/ directory.")
+	cmd.Flags().StringVar(&destBucket, "destBucket", "halconfig2", "The GCS bucket name to write to.")
+	cmd.Flags().StringVar(&prefix, "prefix", "bom/", "The GCS object prefix under --srcBucket holding BOMs.")
+	cmd.Flags().StringVar(&jsonKeyPath, "jsonKey", "", "Filepath to JSON key with permission to read --srcBucket and write to --destBucket.")
+	cmd.Flags().StringArrayVar(&replacements, "replace", []string{"gcr.io/spinnaker-marketplace=us-docker.pkg.dev/spinnaker-community/releases"},
+		"An old=new registry reference to replace, may be repeated.")
+	return cmd
+}
+
+// parseReplacements turns a list of "old=new" flag values into a
+// replacements map for bom.RewriteBOM.
+func parseReplacements(pairs []string) (map[string]string, error) {
+	replacements := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		old, new, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --replace %q, expected old=new", pair)
+		}
+		replacements[old] = new
+	}
+	return replacements, nil
+}
+
+func runBomRewrite(ctx context.Context, srcBucket, destBucket, prefix, jsonKeyPath string, replacements map[string]string) error {
+	storageSvc, err := storage.NewClient(ctx, option.WithCredentialsFile(jsonKeyPath), option.WithScopes(storage.ScopeFullControl))
+	if err != nil {
+		return fmt.Errorf("creating storage client: %w", err)
+	}
+
+	iter := storageSvc.Bucket(srcBucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for obj, err := iter.Next(); err != iterator.Done; obj, err = iter.Next() {
+		if err != nil {
+			return fmt.Errorf("listing %v/%v: %w", srcBucket, prefix, err)
+		}
+		if !bom.IsRelease(obj.Name) {
+			continue
+		}
+
+		if err := rewriteBomObject(ctx, storageSvc, srcBucket, destBucket, obj.Name, replacements); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rewriteBomObject(ctx context.Context, storageSvc *storage.Client, srcBucket, destBucket, name string, replacements map[string]string) error {
+	r, err := storageSvc.Bucket(srcBucket).Object(name).NewReader(ctx)
+	if err != nil {
+		return fmt.Errorf("reading %v: %w", name, err)
+	}
+	defer r.Close()
+
+	w := storageSvc.Bucket(destBucket).Object(name).NewWriter(ctx)
+	w.ObjectAttrs.ContentType = "application/x-yaml"
+
+	if err := bom.RewriteBOM(r, w, replacements); err != nil {
+		w.Close()
+		return fmt.Errorf("rewriting %v: %w", name, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("closing %v: %w", name, err)
+	}
+	return nil
+}
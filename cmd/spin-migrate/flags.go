@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/spf13/cobra"
+	"github.com/spinnaker/buildtool/pkg/migrate"
+	"github.com/spinnaker/buildtool/pkg/registry"
+)
+
+// registryFlags are the registry, signing, and execution flags shared by
+// the migrate-services and migrate-halyard subcommands.
+type registryFlags struct {
+	fromRepo string
+	toRepo   string
+
+	srcRegistryAuth string
+	dstRegistryAuth string
+
+	copySignatures bool
+	verify         bool
+	verifyKey      string
+
+	parallelism int
+	dryRun      bool
+	journalPath string
+
+	emitScript bool
+	outPath    string
+}
+
+// register binds f's fields to flags on cmd. defaultOut is the script path
+// written when --emit-script is set.
+func (f *registryFlags) register(cmd *cobra.Command, defaultOut string) {
+	cmd.Flags().StringVar(&f.fromRepo, "from-project", "gcr.io/spinnaker-marketplace", "Source repository prefix.")
+	cmd.Flags().StringVar(&f.toRepo, "to-project", "us-docker.pkg.dev/spinnaker-community/releases", "Destination repository prefix.")
+
+	cmd.Flags().StringVar(&f.srcRegistryAuth, "src-registry-auth", "", "Path to a docker config.json with credentials for --from-project. Defaults to the ambient Docker config.")
+	cmd.Flags().StringVar(&f.dstRegistryAuth, "dst-registry-auth", "", "Path to a docker config.json with credentials for --to-project. Defaults to the ambient Docker config.")
+
+	cmd.Flags().BoolVar(&f.copySignatures, "copy-signatures", true, "Copy cosign signatures, SBOMs, and attestations alongside each migrated image.")
+	cmd.Flags().BoolVar(&f.verify, "verify", false, "Verify cosign signatures before migrating an image, aborting on failure.")
+	cmd.Flags().StringVar(&f.verifyKey, "verify-key", "", "Path to a cosign public key to verify against. If empty, verification is keyless (Fulcio/Rekor).")
+
+	cmd.Flags().IntVar(&f.parallelism, "parallelism", 0, "Number of images to migrate concurrently. Defaults to GOMAXPROCS.")
+	cmd.Flags().BoolVar(&f.dryRun, "dry-run", false, "Print what would be copied without touching any registry.")
+	cmd.Flags().StringVar(&f.journalPath, "journal", "", "Path to a journal file recording completed migrations, so re-runs skip them. Disabled if empty.")
+
+	cmd.Flags().BoolVar(&f.emitScript, "emit-script", false, "Write a shell script of gcloud add-tag commands instead of migrating in-process.")
+	cmd.Flags().StringVar(&f.outPath, "out", defaultOut, "Script path to write when --emit-script is set.")
+}
+
+// keychains builds the source and destination keychains from f's auth
+// flags.
+func (f *registryFlags) keychains() (src, dst authn.Keychain, err error) {
+	src, err = registry.KeychainFromConfigPath(f.srcRegistryAuth)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading --src-registry-auth: %w", err)
+	}
+	dst, err = registry.KeychainFromConfigPath(f.dstRegistryAuth)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading --dst-registry-auth: %w", err)
+	}
+	return src, dst, nil
+}
+
+// run either emits jobs as a gcloud script (--emit-script) or migrates them
+// in-process via a migrate.Executor, depending on f's flags.
+func (f *registryFlags) run(ctx context.Context, srcAuth, dstAuth authn.Keychain, jobs []migrate.Job) error {
+	if f.emitScript {
+		return writeScript(f.outPath, jobs)
+	}
+
+	var journal *migrate.Journal
+	if f.journalPath != "" {
+		j, err := migrate.OpenJournal(f.journalPath)
+		if err != nil {
+			return fmt.Errorf("opening journal: %w", err)
+		}
+		defer j.Close()
+		journal = j
+	}
+
+	executor := migrate.NewExecutor(migrate.Options{
+		Parallelism:    f.parallelism,
+		DryRun:         f.dryRun,
+		CopySignatures: f.copySignatures,
+		Verify:         f.verify,
+		VerifyOptions:  registry.VerifyOptions{PublicKeyPath: f.verifyKey},
+		SrcKeychain:    srcAuth,
+		DstKeychain:    dstAuth,
+		Journal:        journal,
+	})
+	return executor.Run(ctx, jobs)
+}
+
+// writeScript preserves the behavior of the old one-off binaries: a shell
+// script of `gcloud container images add-tag` invocations for a human to
+// run serially.
+func writeScript(path string, jobs []migrate.Job) error {
+	lines := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		b := strings.Builder{}
+		b.WriteString(fmt.Sprintf("gcloud container images add-tag --quiet %v ", job.SrcRef))
+		for _, dstRef := range job.DstRefs {
+			b.WriteString(fmt.Sprintf("%v ", dstRef))
+		}
+		lines = append(lines, b.String())
+	}
+	sort.Strings(lines)
+	return ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), 0744)
+}
@@ -0,0 +1,32 @@
+// Command spin-migrate rewrites Spinnaker BOMs and migrates Spinnaker
+// container images between OCI registries, replacing the old one-off
+// migrate-boms, migrate-halyard-containers, and images-from-boms binaries.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "spin-migrate",
+		Short:         "Migrate Spinnaker BOMs and container images between registries.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.AddCommand(newBomRewriteCmd())
+	root.AddCommand(newMigrateServicesCmd())
+	root.AddCommand(newMigrateHalyardCmd())
+	return root
+}
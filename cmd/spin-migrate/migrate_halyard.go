@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+	"github.com/spinnaker/buildtool/pkg/migrate"
+	"github.com/spinnaker/buildtool/pkg/registry"
+)
+
+// halyardReleaseRegexp matches released (non-snapshot) halyard image tags.
+var halyardReleaseRegexp = regexp.MustCompile(`^[01]\.[0-9]{1,2}\.[0-9]{1,2}(-slim|-ubuntu)?$`)
+
+func newMigrateHalyardCmd() *cobra.Command {
+	flags := &registryFlags{}
+	cmd := &cobra.Command{
+		Use:   "migrate-halyard",
+		Short: "Migrate released halyard container images from --from-project to --to-project.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrateHalyard(cmd, flags)
+		},
+	}
+	flags.register(cmd, "migrate-halyard-containers.sh")
+	return cmd
+}
+
+func runMigrateHalyard(cmd *cobra.Command, flags *registryFlags) error {
+	srcAuth, dstAuth, err := flags.keychains()
+	if err != nil {
+		return err
+	}
+
+	fromRepo := fmt.Sprintf("%v/halyard", flags.fromRepo)
+	toRepo := fmt.Sprintf("%v/halyard", flags.toRepo)
+
+	images, err := registry.ListTags(fromRepo, srcAuth)
+	if err != nil {
+		return fmt.Errorf("listing tags for %v: %w", fromRepo, err)
+	}
+
+	jobs := make([]migrate.Job, 0, len(images))
+	for _, img := range images {
+		var matchedTag string
+		for _, tag := range img.Tags {
+			if halyardReleaseRegexp.MatchString(tag) {
+				matchedTag = tag
+				break
+			}
+		}
+		if matchedTag == "" {
+			continue
+		}
+
+		dstRefs := make([]string, 0, len(img.Tags))
+		for _, tag := range img.Tags {
+			dstRefs = append(dstRefs, fmt.Sprintf("%v:%v", toRepo, tag))
+		}
+
+		jobs = append(jobs, migrate.Job{
+			Service: "halyard",
+			Tag:     matchedTag,
+			SrcRepo: fromRepo,
+			DstRepo: toRepo,
+			Digest:  img.Digest,
+			SrcRef:  fmt.Sprintf("%v:%v", fromRepo, matchedTag),
+			DstRefs: dstRefs,
+			IsIndex: img.IsIndex,
+		})
+	}
+
+	return flags.run(cmd.Context(), srcAuth, dstAuth, jobs)
+}
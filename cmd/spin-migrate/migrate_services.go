@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/spf13/cobra"
+	"github.com/spinnaker/buildtool/pkg/bom"
+	"github.com/spinnaker/buildtool/pkg/migrate"
+	"github.com/spinnaker/buildtool/pkg/registry"
+	"google.golang.org/api/option"
+)
+
+// variantSuffixes are the build variants published alongside a service's
+// default image, e.g. clouddriver:6.3.0-slim.
+var variantSuffixes = []string{
+	"",
+	"-slim",
+	"-ubuntu",
+	"-java8",
+	"-ubuntu-java8",
+}
+
+func newMigrateServicesCmd() *cobra.Command {
+	var bucket, prefix, jsonKeyPath string
+	flags := &registryFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "migrate-services",
+		Short: "Migrate every Spinnaker service image referenced by the BOMs in a GCS bucket.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrateServices(cmd, flags, bucket, prefix, jsonKeyPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&bucket, "bucket", "halconfig", "The GCS bucket to read BOMs from.")
+	cmd.Flags().StringVar(&prefix, "prefix", "bom/", "The GCS object prefix under --bucket holding BOMs.")
+	cmd.Flags().StringVar(&jsonKeyPath, "jsonKey", "", "Filepath to a JSON key with permission to read --bucket.")
+	flags.register(cmd, "migrate-containers.sh")
+	return cmd
+}
+
+func runMigrateServices(cmd *cobra.Command, flags *registryFlags, bucket, prefix, jsonKeyPath string) error {
+	ctx := cmd.Context()
+
+	storageSvc, err := storage.NewClient(ctx, option.WithCredentialsFile(jsonKeyPath), option.WithScopes(storage.ScopeReadOnly))
+	if err != nil {
+		return fmt.Errorf("creating storage client: %w", err)
+	}
+
+	srcAuth, dstAuth, err := flags.keychains()
+	if err != nil {
+		return err
+	}
+
+	boms, err := bom.LoadFromGCS(ctx, storageSvc, bucket, prefix)
+	if err != nil {
+		return fmt.Errorf("loading boms: %w", err)
+	}
+	if len(boms) == 0 {
+		return fmt.Errorf("no boms found under gs://%v/%v", bucket, prefix)
+	}
+
+	imagesByTag, err := indexImagesByTag(boms[0], flags.fromRepo, srcAuth)
+	if err != nil {
+		return err
+	}
+
+	jobs := buildServiceJobs(boms, imagesByTag, flags.fromRepo, flags.toRepo)
+	return flags.run(ctx, srcAuth, dstAuth, jobs)
+}
+
+// indexImagesByTag lists every tag of every service declared by ref,
+// keyed as "<service>:<tag>".
+func indexImagesByTag(ref *bom.Bom, fromRepo string, srcAuth authn.Keychain) (map[string]*registry.Image, error) {
+	imagesByTag := make(map[string]*registry.Image, 1000)
+
+	l := ref.Services.List()
+	for svcElem := l.Front(); svcElem != nil; svcElem = svcElem.Next() {
+		svc := svcElem.Value.(*bom.Service)
+		repo := fmt.Sprintf("%v/%v", fromRepo, svc.Name())
+		images, err := registry.ListTags(repo, srcAuth)
+		if err != nil {
+			return nil, fmt.Errorf("listing tags for %v: %w", repo, err)
+		}
+
+		for _, img := range images {
+			for _, tag := range img.Tags {
+				imagesByTag[fmt.Sprintf("%v:%v", svc.Name(), tag)] = img
+			}
+		}
+	}
+	return imagesByTag, nil
+}
+
+// buildServiceJobs resolves, for every service in every bom, the migrate.Job
+// needed to copy its pinned image (and every variant suffix) to toRepo.
+func buildServiceJobs(boms []*bom.Bom, imagesByTag map[string]*registry.Image, fromRepo, toRepo string) []migrate.Job {
+	jobsByRef := make(map[string]*migrate.Job)
+	// dstRefsSeen tracks, per srcRef, which DstRefs have already been
+	// appended to that job, since the same srcRef recurs across every BOM
+	// that happens to pin the same service version (the common case between
+	// point releases) and would otherwise accumulate duplicate entries.
+	dstRefsSeen := make(map[string]map[string]bool)
+
+	for _, b := range boms {
+		l := b.Services.List()
+		for svcElem := l.Front(); svcElem != nil; svcElem = svcElem.Next() {
+			svc := svcElem.Value.(*bom.Service)
+
+			for _, suffix := range variantSuffixes {
+				fullTag := fmt.Sprintf("%v:%v%v", svc.Name(), svc.Version, suffix)
+				img, ok := imagesByTag[fullTag]
+				if !ok {
+					continue
+				}
+
+				srcRepo := fmt.Sprintf("%v/%v", fromRepo, svc.Name())
+				dstRepo := fmt.Sprintf("%v/%v", toRepo, svc.Name())
+				srcRef := fmt.Sprintf("%v:%v%v", srcRepo, svc.Version, suffix)
+
+				job, ok := jobsByRef[srcRef]
+				if !ok {
+					job = &migrate.Job{
+						BOMVersion: b.Version,
+						Service:    svc.Name(),
+						Tag:        svc.Version + suffix,
+						SrcRepo:    srcRepo,
+						DstRepo:    dstRepo,
+						Digest:     img.Digest,
+						SrcRef:     srcRef,
+						IsIndex:    img.IsIndex,
+					}
+					jobsByRef[srcRef] = job
+					dstRefsSeen[srcRef] = make(map[string]bool)
+				}
+				seen := dstRefsSeen[srcRef]
+				addDstRef := func(ref string) {
+					if seen[ref] {
+						return
+					}
+					seen[ref] = true
+					job.DstRefs = append(job.DstRefs, ref)
+				}
+
+				if suffix == "" || suffix == "-slim" {
+					// Only the Alpine-based variants get the top-level BOM version, since that's the preferred default.
+					// This means for 1.16+, this puts the top-level BOM version on both the empty-suffix and "-slim"
+					// variant, but this is a no-op because both tags point to the same digest anyway.
+					addDstRef(fmt.Sprintf("%v:spinnaker-%v", dstRepo, b.Version))
+				}
+				for _, tag := range img.Tags {
+					addDstRef(fmt.Sprintf("%v:%v", dstRepo, tag))
+				}
+			}
+		}
+	}
+
+	jobs := make([]migrate.Job, 0, len(jobsByRef))
+	for _, job := range jobsByRef {
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
@@ -0,0 +1,139 @@
+// Package bom reads and rewrites Spinnaker BOM (Bill of Materials) YAML
+// files stored in GCS, and enumerates the services and dependencies they
+// declare.
+package bom
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"gopkg.in/yaml.v2"
+)
+
+// releaseRegexp matches released BOM object names, e.g. "bom/1.16.0.yml".
+var releaseRegexp = regexp.MustCompile(`1\.[0-9]{1,2}\.[0-9]{1,2}\.yml`)
+
+// Bom is a single Spinnaker BOM.
+type Bom struct {
+	Version      string       `yaml:"version"`
+	Timestamp    string       `yaml:"timestamp"`
+	Services     Services     `yaml:"services"`
+	Dependencies Dependencies `yaml:"dependencies"`
+}
+
+// Services holds the version pinned for each Spinnaker microservice.
+type Services struct {
+	Clouddriver      Service `yaml:"clouddriver"`
+	Deck             Service `yaml:"deck"`
+	Echo             Service `yaml:"echo"`
+	Fiat             Service `yaml:"fiat"`
+	Front50          Service `yaml:"front50"`
+	Gate             Service `yaml:"gate"`
+	Igor             Service `yaml:"igor"`
+	Kayenta          Service `yaml:"kayenta"`
+	MonitoringDaemon Service `yaml:"monitoring-daemon"`
+	Orca             Service `yaml:"orca"`
+	Rosco            Service `yaml:"rosco"`
+}
+
+// List returns every service in s, each tagged with its field name via
+// Service.name, in a stable order.
+func (s *Services) List() *list.List {
+	l := list.New()
+	l.PushBack(s.Clouddriver.WithName("clouddriver"))
+	l.PushBack(s.Deck.WithName("deck"))
+	l.PushBack(s.Echo.WithName("echo"))
+	l.PushBack(s.Fiat.WithName("fiat"))
+	l.PushBack(s.Front50.WithName("front50"))
+	l.PushBack(s.Gate.WithName("gate"))
+	l.PushBack(s.Igor.WithName("igor"))
+	l.PushBack(s.Kayenta.WithName("kayenta"))
+	l.PushBack(s.MonitoringDaemon.WithName("monitoring-daemon"))
+	l.PushBack(s.Orca.WithName("orca"))
+	l.PushBack(s.Rosco.WithName("rosco"))
+	return l
+}
+
+// Dependencies holds the version pinned for each of Spinnaker's external
+// dependencies.
+type Dependencies struct {
+	Consul Service `yaml:"consul"`
+	Redis  Service `yaml:"redis"`
+	Vault  Service `yaml:"vault"`
+}
+
+// Service is a single versioned component of a BOM.
+type Service struct {
+	Commit  string `yaml:"commit,omitempty"`
+	Version string `yaml:"version"`
+	name    string
+}
+
+// Name returns the field name List tagged this Service with.
+func (s *Service) Name() string {
+	return s.name
+}
+
+// WithName tags s with its field name and returns it, for chaining into
+// List.
+func (s *Service) WithName(n string) *Service {
+	s.name = n
+	return s
+}
+
+// IsRelease reports whether objectName looks like a released BOM, e.g.
+// "bom/1.16.0.yml".
+func IsRelease(objectName string) bool {
+	return releaseRegexp.MatchString(objectName)
+}
+
+// LoadFromGCS reads and decodes every released BOM (bom/<version>.yml) in
+// bucket under prefix, in the order returned by the GCS object listing.
+func LoadFromGCS(ctx context.Context, client *storage.Client, bucket, prefix string) ([]*Bom, error) {
+	boms := make([]*Bom, 0, 100)
+	iter := client.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for obj, err := iter.Next(); err != iterator.Done; obj, err = iter.Next() {
+		if err != nil {
+			return nil, fmt.Errorf("listing %v/%v: %w", bucket, prefix, err)
+		}
+		if !IsRelease(obj.Name) {
+			continue
+		}
+
+		r, err := client.Bucket(bucket).Object(obj.Name).NewReader(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("reading %v: %w", obj.Name, err)
+		}
+		b := &Bom{}
+		err = yaml.NewDecoder(r).Decode(b)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding %v: %w", obj.Name, err)
+		}
+		boms = append(boms, b)
+	}
+	return boms, nil
+}
+
+// RewriteBOM copies r to w line by line, replacing every occurrence of each
+// key in replacements with its value.
+func RewriteBOM(r io.Reader, w io.Writer, replacements map[string]string) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		for old, new := range replacements {
+			line = strings.ReplaceAll(line, old, new)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("writing line: %w", err)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// JournalEntry records one completed source->destination copy.
+type JournalEntry struct {
+	SrcRef  string   `json:"srcRef"`
+	Digest  string   `json:"digest"`
+	DstRefs []string `json:"dstRefs"`
+}
+
+// Journal is an append-only, line-delimited JSON log of completed copies,
+// keyed by destination ref rather than source ref: a source whose job later
+// needs one additional destination tag (e.g. a new BOM version alias) must
+// still run, even though that source was migrated before. Re-running an
+// Executor against the same Journal skips a job only when every one of its
+// destinations is already recorded, making migrations idempotent.
+type Journal struct {
+	mu   sync.Mutex
+	f    *os.File
+	done map[string]bool // destination ref -> already written
+}
+
+// OpenJournal opens (creating if necessary) the journal file at path,
+// replaying any existing entries so Done reflects prior runs.
+func OpenJournal(path string) (*Journal, error) {
+	done := make(map[string]bool)
+	if existing, err := os.Open(path); err == nil {
+		dec := json.NewDecoder(existing)
+		for {
+			var entry JournalEntry
+			if err := dec.Decode(&entry); err != nil {
+				break
+			}
+			for _, dstRef := range entry.DstRefs {
+				done[dstRef] = true
+			}
+		}
+		existing.Close()
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading journal %q: %w", path, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening journal %q: %w", path, err)
+	}
+	return &Journal{f: f, done: done}, nil
+}
+
+// Done reports whether every ref in dstRefs has already been recorded as
+// written, so a job is skipped only once none of its destinations are new.
+func (j *Journal) Done(dstRefs []string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, dstRef := range dstRefs {
+		if !j.done[dstRef] {
+			return false
+		}
+	}
+	return true
+}
+
+// Record appends a completed copy to the journal.
+func (j *Journal) Record(srcRef, digest string, dstRefs []string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	b, err := json.Marshal(JournalEntry{SrcRef: srcRef, Digest: digest, DstRefs: dstRefs})
+	if err != nil {
+		return err
+	}
+	if _, err := j.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	for _, dstRef := range dstRefs {
+		j.done[dstRef] = true
+	}
+	return nil
+}
+
+// Close closes the underlying journal file.
+func (j *Journal) Close() error {
+	return j.f.Close()
+}
@@ -0,0 +1,48 @@
+package migrate
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestJournalDoneKeyedByDestRef guards against a prior regression where
+// Done checked only SrcRef, so a job that later needs one more destination
+// tag for an already-migrated source (e.g. a new BOM version alias) was
+// silently skipped instead of being re-run to add the new tag.
+func TestJournalDoneKeyedByDestRef(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+
+	if err := j.Record("repo/a:1", "sha256:abc", []string{"repo/a:1", "repo/a:spinnaker-1.0.0"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	if !j.Done([]string{"repo/a:1", "repo/a:spinnaker-1.0.0"}) {
+		t.Errorf("Done(already-recorded refs) = false, want true")
+	}
+	if j.Done([]string{"repo/a:1", "repo/a:spinnaker-1.1.0"}) {
+		t.Errorf("Done(refs including a new destination tag) = true, want false so the job reruns to write it")
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// A fresh Journal opened against the same file must replay the same
+	// destination-ref state.
+	j2, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("re-OpenJournal: %v", err)
+	}
+	defer j2.Close()
+
+	if !j2.Done([]string{"repo/a:1", "repo/a:spinnaker-1.0.0"}) {
+		t.Errorf("after replay, Done(already-recorded refs) = false, want true")
+	}
+	if j2.Done([]string{"repo/a:spinnaker-1.1.0"}) {
+		t.Errorf("after replay, Done(never-recorded ref) = true, want false")
+	}
+}
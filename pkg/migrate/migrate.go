@@ -0,0 +1,211 @@
+// Package migrate runs image re-tagging jobs concurrently in-process,
+// replacing the old pattern of emitting a shell script of gcloud commands
+// for a human to run serially.
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/spinnaker/buildtool/pkg/registry"
+)
+
+// Job is a single image to copy from SrcRef to every reference in DstRefs.
+// SrcRepo, DstRepo, and Digest are only needed when CopySignatures is set.
+type Job struct {
+	BOMVersion string
+	Service    string
+	Tag        string
+	SrcRepo    string
+	DstRepo    string
+	Digest     string
+	SrcRef     string
+	DstRefs    []string
+	// IsIndex marks SrcRef as a manifest list or OCI image index, copied via
+	// registry.CopyIndex rather than a single-platform remote.Write.
+	IsIndex bool
+}
+
+// Event is a single structured progress entry, emitted as one line of JSON
+// per copy start/success/error.
+type Event struct {
+	Phase      string   `json:"phase"` // "start", "success", or "error"
+	BOMVersion string   `json:"bomVersion,omitempty"`
+	Service    string   `json:"service,omitempty"`
+	Tag        string   `json:"tag,omitempty"`
+	SrcRef     string   `json:"srcRef"`
+	DstRefs    []string `json:"dstRefs,omitempty"`
+	Digest     string   `json:"digest,omitempty"`
+	IsIndex    bool     `json:"isIndex,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Options configures an Executor.
+type Options struct {
+	// Parallelism is the number of jobs to run at once. Defaults to
+	// GOMAXPROCS when <= 0.
+	Parallelism int
+	// DryRun logs what would be copied without contacting any registry.
+	DryRun bool
+	// CopySignatures also copies cosign signatures, SBOMs, and attestations
+	// for each job's Digest.
+	CopySignatures bool
+	// Verify runs cosign verification against SrcRef before copying,
+	// aborting that job on failure.
+	Verify        bool
+	VerifyOptions registry.VerifyOptions
+
+	SrcKeychain authn.Keychain
+	DstKeychain authn.Keychain
+
+	// Journal, if set, skips jobs already recorded as complete and records
+	// newly completed ones, making re-runs idempotent.
+	Journal *Journal
+	// Progress receives one JSON-encoded Event per line. Defaults to
+	// os.Stdout.
+	Progress io.Writer
+}
+
+// Executor runs a set of Jobs with a bounded worker pool.
+type Executor struct {
+	opts   Options
+	emitMu sync.Mutex
+}
+
+// NewExecutor builds an Executor from opts, applying defaults for
+// Parallelism and Progress.
+func NewExecutor(opts Options) *Executor {
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = runtime.GOMAXPROCS(0)
+	}
+	if opts.Progress == nil {
+		opts.Progress = os.Stdout
+	}
+	return &Executor{opts: opts}
+}
+
+// Run copies every job not already recorded in the Journal, up to
+// Parallelism at a time. If any job failed, it returns an error that counts
+// the failures and wraps the first one.
+//
+// Jobs are deduplicated by destination ref first: two jobs are never allowed
+// to write the same DstRef concurrently (or at all, the second time), since
+// nothing else serializes concurrent writers targeting an identical tag.
+func (e *Executor) Run(ctx context.Context, jobs []Job) error {
+	jobs = dedupeByDstRef(jobs)
+
+	sem := make(chan struct{}, e.opts.Parallelism)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+
+	for _, job := range jobs {
+		if e.opts.Journal != nil && e.opts.Journal.Done(job.DstRefs) {
+			continue
+		}
+
+		job := job
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := e.runJob(ctx, job); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d migrations failed: %w", len(errs), len(jobs), errs[0])
+	}
+	return nil
+}
+
+// dedupeByDstRef claims each destination ref for the first job that names
+// it, in input order, and strips it from every later job. A job left with
+// no DstRefs is dropped entirely: its destinations are already covered by
+// an earlier job, so running it would just race a second writer against the
+// same tag (e.g. a service's default and "-slim" variants both landing on
+// the shared "spinnaker-<bom version>" tag).
+func dedupeByDstRef(jobs []Job) []Job {
+	claimed := make(map[string]bool)
+	out := make([]Job, 0, len(jobs))
+	for _, job := range jobs {
+		kept := make([]string, 0, len(job.DstRefs))
+		for _, ref := range job.DstRefs {
+			if claimed[ref] {
+				continue
+			}
+			claimed[ref] = true
+			kept = append(kept, ref)
+		}
+		job.DstRefs = kept
+		if len(job.DstRefs) > 0 {
+			out = append(out, job)
+		}
+	}
+	return out
+}
+
+func (e *Executor) runJob(ctx context.Context, job Job) error {
+	e.emit(Event{Phase: "start", BOMVersion: job.BOMVersion, Service: job.Service, Tag: job.Tag, SrcRef: job.SrcRef, DstRefs: job.DstRefs, Digest: job.Digest, IsIndex: job.IsIndex})
+
+	if e.opts.DryRun {
+		e.emit(Event{Phase: "success", BOMVersion: job.BOMVersion, Service: job.Service, Tag: job.Tag, SrcRef: job.SrcRef, DstRefs: job.DstRefs, Digest: job.Digest, IsIndex: job.IsIndex})
+		return nil
+	}
+
+	if err := e.copy(ctx, job); err != nil {
+		e.emit(Event{Phase: "error", BOMVersion: job.BOMVersion, Service: job.Service, Tag: job.Tag, SrcRef: job.SrcRef, DstRefs: job.DstRefs, Digest: job.Digest, IsIndex: job.IsIndex, Error: err.Error()})
+		return fmt.Errorf("migrating %v: %w", job.SrcRef, err)
+	}
+
+	e.emit(Event{Phase: "success", BOMVersion: job.BOMVersion, Service: job.Service, Tag: job.Tag, SrcRef: job.SrcRef, DstRefs: job.DstRefs, Digest: job.Digest, IsIndex: job.IsIndex})
+
+	if e.opts.Journal != nil {
+		if err := e.opts.Journal.Record(job.SrcRef, job.Digest, job.DstRefs); err != nil {
+			return fmt.Errorf("recording journal entry for %v: %w", job.SrcRef, err)
+		}
+	}
+	return nil
+}
+
+func (e *Executor) copy(ctx context.Context, job Job) error {
+	if e.opts.Verify {
+		if err := registry.Verify(ctx, job.SrcRef, e.opts.SrcKeychain, e.opts.VerifyOptions); err != nil {
+			return fmt.Errorf("verifying: %w", err)
+		}
+	}
+
+	if err := registry.Retag(job.SrcRef, e.opts.SrcKeychain, e.opts.DstKeychain, job.DstRefs...); err != nil {
+		return err
+	}
+
+	if e.opts.CopySignatures {
+		if err := registry.CopySignatures(job.SrcRepo, job.DstRepo, job.Digest, e.opts.SrcKeychain, e.opts.DstKeychain); err != nil {
+			return fmt.Errorf("copying signatures: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *Executor) emit(ev Event) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	e.emitMu.Lock()
+	defer e.emitMu.Unlock()
+	fmt.Fprintln(e.opts.Progress, string(b))
+}
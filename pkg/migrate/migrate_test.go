@@ -0,0 +1,47 @@
+package migrate
+
+import "testing"
+
+func TestDedupeByDstRef(t *testing.T) {
+	jobs := []Job{
+		{SrcRef: "repo/a:1", DstRefs: []string{"repo/a:1", "repo/a:spinnaker-1.0.0"}},
+		{SrcRef: "repo/a:1-slim", DstRefs: []string{"repo/a:1-slim", "repo/a:spinnaker-1.0.0"}},
+		{SrcRef: "repo/b:1", DstRefs: []string{"repo/b:1"}},
+	}
+
+	got := dedupeByDstRef(jobs)
+
+	if len(got) != 3 {
+		t.Fatalf("len(got) = %d, want 3", len(got))
+	}
+
+	if diff := got[0].DstRefs; len(diff) != 2 || diff[0] != "repo/a:1" || diff[1] != "repo/a:spinnaker-1.0.0" {
+		t.Errorf("got[0].DstRefs = %v, want [repo/a:1 repo/a:spinnaker-1.0.0]", diff)
+	}
+
+	// repo/a:spinnaker-1.0.0 was already claimed by the first job, so the
+	// second job (the "-slim" variant) should keep only its own tag.
+	if diff := got[1].DstRefs; len(diff) != 1 || diff[0] != "repo/a:1-slim" {
+		t.Errorf("got[1].DstRefs = %v, want [repo/a:1-slim]", diff)
+	}
+
+	if diff := got[2].DstRefs; len(diff) != 1 || diff[0] != "repo/b:1" {
+		t.Errorf("got[2].DstRefs = %v, want [repo/b:1]", diff)
+	}
+}
+
+func TestDedupeByDstRefDropsJobsLeftWithNoDstRefs(t *testing.T) {
+	jobs := []Job{
+		{SrcRef: "repo/a:1", DstRefs: []string{"repo/a:spinnaker-1.0.0"}},
+		{SrcRef: "repo/a:1-slim", DstRefs: []string{"repo/a:spinnaker-1.0.0"}},
+	}
+
+	got := dedupeByDstRef(jobs)
+
+	if len(got) != 1 {
+		t.Fatalf("len(got) = %d, want 1 (second job's only DstRef was already claimed)", len(got))
+	}
+	if got[0].SrcRef != "repo/a:1" {
+		t.Errorf("got[0].SrcRef = %v, want repo/a:1", got[0].SrcRef)
+	}
+}
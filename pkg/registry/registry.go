@@ -0,0 +1,254 @@
+// Package registry provides a native OCI Docker Registry v2 client used by
+// the image migration tools, replacing ad-hoc shell-outs to the gcloud CLI.
+// It can talk to any registry that implements the standard API (GCR,
+// Artifact Registry, ECR, Harbor, GHCR, Artifactory, ...).
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// Image is a single digest in a repository together with every tag that
+// currently points at it. If the digest names a manifest list / image
+// index, IsIndex is set and Children describes its per-platform manifests.
+type Image struct {
+	Digest   string
+	Tags     []string
+	IsIndex  bool
+	Children []ChildManifest
+}
+
+// ChildManifest is one platform-specific manifest referenced by a manifest
+// list or OCI image index.
+type ChildManifest struct {
+	Digest   string
+	Platform Platform
+}
+
+// Platform identifies the OS/architecture a child manifest was built for.
+type Platform struct {
+	OS           string
+	Architecture string
+	Variant      string
+}
+
+// ListTags returns every image in repo, grouping tags that share a digest
+// the same way `gcloud container images list-tags` does. Manifest lists and
+// OCI image indexes are detected and their child manifests populated. Tags
+// are resolved with a cheap HEAD request first; the more expensive manifest
+// body is only fetched for tags that turn out to be indexes.
+func ListTags(repo string, keychain authn.Keychain) ([]*Image, error) {
+	r, err := name.NewRepository(repo)
+	if err != nil {
+		return nil, fmt.Errorf("parsing repository %q: %w", repo, err)
+	}
+
+	tags, err := remote.List(r, remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return nil, fmt.Errorf("listing tags for %q: %w", repo, err)
+	}
+
+	byDigest := make(map[string]*Image, len(tags))
+	order := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		ref := r.Tag(tag)
+		head, err := remote.Head(ref, remote.WithAuthFromKeychain(keychain))
+		if err != nil {
+			return nil, fmt.Errorf("resolving %v:%v: %w", repo, tag, err)
+		}
+
+		digest := head.Digest.String()
+		img, ok := byDigest[digest]
+		if !ok {
+			img = &Image{Digest: digest}
+			if head.MediaType.IsIndex() {
+				if err := populateIndex(img, ref, keychain); err != nil {
+					return nil, fmt.Errorf("inspecting %v:%v: %w", repo, tag, err)
+				}
+			}
+			byDigest[digest] = img
+			order = append(order, digest)
+		}
+		img.Tags = append(img.Tags, tag)
+	}
+
+	images := make([]*Image, 0, len(order))
+	for _, digest := range order {
+		images = append(images, byDigest[digest])
+	}
+	return images, nil
+}
+
+// populateIndex fetches ref's manifest body and fills in img.IsIndex and
+// img.Children. Callers should only reach for this once a cheap HEAD request
+// has already confirmed ref names a manifest list or image index.
+func populateIndex(img *Image, ref name.Reference, keychain authn.Keychain) error {
+	desc, err := remote.Get(ref, remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return fmt.Errorf("fetching %v: %w", ref, err)
+	}
+
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return fmt.Errorf("reading image index: %w", err)
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return fmt.Errorf("reading index manifest: %w", err)
+	}
+
+	img.IsIndex = true
+	for _, child := range manifest.Manifests {
+		c := ChildManifest{Digest: child.Digest.String()}
+		if child.Platform != nil {
+			c.Platform = Platform{
+				OS:           child.Platform.OS,
+				Architecture: child.Platform.Architecture,
+				Variant:      child.Platform.Variant,
+			}
+		}
+		img.Children = append(img.Children, c)
+	}
+	return nil
+}
+
+// Retag fetches srcRef with srcKeychain and writes it under every reference
+// in dstRefs with dstKeychain, re-uploading layers to the destination
+// registries where necessary. If srcRef names a manifest list or image
+// index, each destination gets a copy of that index, written after its
+// children have been copied; see CopyIndex.
+func Retag(srcRef string, srcKeychain, dstKeychain authn.Keychain, dstRefs ...string) error {
+	src, err := name.ParseReference(srcRef)
+	if err != nil {
+		return fmt.Errorf("parsing source reference %q: %w", srcRef, err)
+	}
+
+	desc, err := remote.Get(src, remote.WithAuthFromKeychain(srcKeychain))
+	if err != nil {
+		return fmt.Errorf("fetching %v: %w", src, err)
+	}
+
+	if desc.MediaType.IsIndex() {
+		for _, dstRef := range dstRefs {
+			if err := CopyIndex(srcRef, dstRef, srcKeychain, dstKeychain); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	img, err := desc.Image()
+	if err != nil {
+		return fmt.Errorf("reading image for %v: %w", src, err)
+	}
+
+	for _, dstRef := range dstRefs {
+		dst, err := name.ParseReference(dstRef)
+		if err != nil {
+			return fmt.Errorf("parsing destination reference %q: %w", dstRef, err)
+		}
+		if err := remote.Write(dst, img, remote.WithAuthFromKeychain(dstKeychain)); err != nil {
+			return fmt.Errorf("writing %v: %w", dst, err)
+		}
+	}
+	return nil
+}
+
+// CopyIndex copies a manifest list or OCI image index from src to dst,
+// writing every child manifest to dst's registry (mounting blobs across
+// repositories in the same registry where possible) before writing the
+// source index itself, unchanged.
+func CopyIndex(src, dst string, srcKeychain, dstKeychain authn.Keychain) error {
+	srcRef, err := name.ParseReference(src)
+	if err != nil {
+		return fmt.Errorf("parsing source reference %q: %w", src, err)
+	}
+
+	desc, err := remote.Get(srcRef, remote.WithAuthFromKeychain(srcKeychain))
+	if err != nil {
+		return fmt.Errorf("fetching %v: %w", srcRef, err)
+	}
+	if !desc.MediaType.IsIndex() {
+		return fmt.Errorf("%v is not a manifest list or image index (got %v)", srcRef, desc.MediaType)
+	}
+
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return fmt.Errorf("reading image index for %v: %w", srcRef, err)
+	}
+
+	dstRef, err := name.ParseReference(dst)
+	if err != nil {
+		return fmt.Errorf("parsing destination reference %q: %w", dst, err)
+	}
+
+	// remote.WriteIndex writes every child manifest referenced by idx before
+	// writing the index itself, mounting blobs across repositories in the
+	// same registry instead of re-uploading them where the registry supports it.
+	if err := remote.WriteIndex(dstRef, idx, remote.WithAuthFromKeychain(dstKeychain)); err != nil {
+		return fmt.Errorf("writing index %v: %w", dstRef, err)
+	}
+	return nil
+}
+
+// dockerConfig is the subset of ~/.docker/config.json that carries registry
+// credentials.
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth string `json:"auth"`
+	} `json:"auths"`
+}
+
+// staticKeychain resolves credentials loaded once from a docker config file.
+type staticKeychain struct {
+	creds map[string]authn.AuthConfig
+}
+
+func (k *staticKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	if cfg, ok := k.creds[target.RegistryStr()]; ok {
+		return authn.FromConfig(cfg), nil
+	}
+	return authn.Anonymous, nil
+}
+
+// KeychainFromConfigPath builds a keychain from a docker config.json at
+// path. An empty path falls back to authn.DefaultKeychain, which resolves
+// credentials from the ambient Docker/Podman config.
+func KeychainFromConfigPath(path string) (authn.Keychain, error) {
+	if path == "" {
+		return authn.DefaultKeychain, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading docker config %q: %w", path, err)
+	}
+
+	var cfg dockerConfig
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing docker config %q: %w", path, err)
+	}
+
+	creds := make(map[string]authn.AuthConfig, len(cfg.Auths))
+	for registry, entry := range cfg.Auths {
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("decoding auth for %q: %w", registry, err)
+		}
+		user, pass, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed auth for %q", registry)
+		}
+		creds[registry] = authn.AuthConfig{Username: user, Password: pass}
+	}
+
+	return &staticKeychain{creds: creds}, nil
+}
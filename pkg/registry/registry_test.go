@@ -0,0 +1,191 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	ggcrregistry "github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// recordingKeychain records the registries it was asked to authenticate
+// against, so tests can assert which keychain a call actually used.
+type recordingKeychain struct {
+	resolved []string
+}
+
+func (k *recordingKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	k.resolved = append(k.resolved, target.RegistryStr())
+	return authn.Anonymous, nil
+}
+
+func (k *recordingKeychain) used(registry string) bool {
+	for _, r := range k.resolved {
+		if r == registry {
+			return true
+		}
+	}
+	return false
+}
+
+// TestRetagUsesDistinctKeychains guards against a prior regression where
+// Retag authenticated both the fetch from srcRef and the write to every
+// dstRef with a single shared keychain, silently ignoring distinct
+// --src-registry-auth / --dst-registry-auth credentials.
+func TestRetagUsesDistinctKeychains(t *testing.T) {
+	src := httptest.NewServer(ggcrregistry.New())
+	defer src.Close()
+	dst := httptest.NewServer(ggcrregistry.New())
+	defer dst.Close()
+
+	srcHost := src.Listener.Addr().String()
+	dstHost := dst.Listener.Addr().String()
+
+	srcRef := srcHost + "/repo:source"
+	dstRef := dstHost + "/repo:dest"
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatalf("building test image: %v", err)
+	}
+	ref, err := name.ParseReference(srcRef)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", srcRef, err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatalf("seeding %v: %v", srcRef, err)
+	}
+
+	srcKeychain := &recordingKeychain{}
+	dstKeychain := &recordingKeychain{}
+	if err := Retag(srcRef, srcKeychain, dstKeychain, dstRef); err != nil {
+		t.Fatalf("Retag(%v, %v): %v", srcRef, dstRef, err)
+	}
+
+	if !srcKeychain.used(srcHost) {
+		t.Errorf("srcKeychain never resolved for %v; got %v", srcHost, srcKeychain.resolved)
+	}
+	if srcKeychain.used(dstHost) {
+		t.Errorf("srcKeychain resolved for %v, but only dstKeychain should authenticate writes there", dstHost)
+	}
+	if !dstKeychain.used(dstHost) {
+		t.Errorf("dstKeychain never resolved for %v; got %v", dstHost, dstKeychain.resolved)
+	}
+	if dstKeychain.used(srcHost) {
+		t.Errorf("dstKeychain resolved for %v, but only srcKeychain should authenticate the fetch there", srcHost)
+	}
+}
+
+// manifestRequestRecorder wraps a registry handler, recording the HTTP
+// method used against every manifest URL it sees.
+type manifestRequestRecorder struct {
+	http.Handler
+	mu      sync.Mutex
+	methods map[string][]string
+}
+
+func newManifestRequestRecorder(h http.Handler) *manifestRequestRecorder {
+	return &manifestRequestRecorder{Handler: h, methods: make(map[string][]string)}
+}
+
+func (r *manifestRequestRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if strings.Contains(req.URL.Path, "/manifests/") {
+		r.mu.Lock()
+		r.methods[req.URL.Path] = append(r.methods[req.URL.Path], req.Method)
+		r.mu.Unlock()
+	}
+	r.Handler.ServeHTTP(w, req)
+}
+
+func (r *manifestRequestRecorder) saw(path, method string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.methods[path] {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// TestListTagsOnlyFetchesManifestBodyForIndexes guards against a prior
+// regression where ListTags called remote.Get (a full manifest body
+// download) for every tag in a repository instead of a cheap remote.Head,
+// only paying for the body once a tag turns out to be a manifest list or
+// image index.
+func TestListTagsOnlyFetchesManifestBodyForIndexes(t *testing.T) {
+	rec := newManifestRequestRecorder(ggcrregistry.New())
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	repo := srv.Listener.Addr().String() + "/repo"
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatalf("building test image: %v", err)
+	}
+	plainRef, err := name.ParseReference(repo + ":plain")
+	if err != nil {
+		t.Fatalf("parsing plain ref: %v", err)
+	}
+	if err := remote.Write(plainRef, img); err != nil {
+		t.Fatalf("seeding plain image: %v", err)
+	}
+
+	idx, err := random.Index(1024, 1, 2)
+	if err != nil {
+		t.Fatalf("building test index: %v", err)
+	}
+	idxRef, err := name.ParseReference(repo + ":index")
+	if err != nil {
+		t.Fatalf("parsing index ref: %v", err)
+	}
+	if err := remote.WriteIndex(idxRef, idx); err != nil {
+		t.Fatalf("seeding index: %v", err)
+	}
+
+	images, err := ListTags(repo, authn.Anonymous)
+	if err != nil {
+		t.Fatalf("ListTags(%v): %v", repo, err)
+	}
+
+	plainPath := "/v2/repo/manifests/plain"
+	if !rec.saw(plainPath, http.MethodHead) {
+		t.Errorf("expected a HEAD request for %v, saw %v", plainPath, rec.methods[plainPath])
+	}
+	if rec.saw(plainPath, http.MethodGet) {
+		t.Errorf("expected no GET request for non-index tag %v, saw %v", plainPath, rec.methods[plainPath])
+	}
+
+	idxPath := "/v2/repo/manifests/index"
+	if !rec.saw(idxPath, http.MethodHead) {
+		t.Errorf("expected a HEAD request for %v, saw %v", idxPath, rec.methods[idxPath])
+	}
+	if !rec.saw(idxPath, http.MethodGet) {
+		t.Errorf("expected a GET request for index tag %v to populate Children, saw %v", idxPath, rec.methods[idxPath])
+	}
+
+	var gotIdx *Image
+	for _, i := range images {
+		for _, tag := range i.Tags {
+			if tag == "index" {
+				gotIdx = i
+			}
+		}
+	}
+	if gotIdx == nil {
+		t.Fatalf("no Image found for tag %q among %v", "index", images)
+	}
+	if !gotIdx.IsIndex {
+		t.Errorf("Image for index tag has IsIndex = false, want true")
+	}
+	if len(gotIdx.Children) != 2 {
+		t.Errorf("len(Children) = %d, want 2", len(gotIdx.Children))
+	}
+}
@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// cosignSuffixes are the sibling-tag suffixes cosign and related tools
+// publish alongside a signed digest: the signature itself, an attached
+// SBOM, and in-toto attestations.
+var cosignSuffixes = []string{".sig", ".sbom", ".att"}
+
+// SignatureRefs returns the cosign sibling-tag references (signature, SBOM,
+// attestation) that may exist for digest in repo, following the
+// "sha256-<hex>.<suffix>" convention. Not every suffix is guaranteed to
+// exist; callers should treat a missing tag as nothing-to-copy rather than
+// an error.
+func SignatureRefs(repo, digest string) []string {
+	hex := strings.TrimPrefix(digest, "sha256:")
+	refs := make([]string, 0, len(cosignSuffixes))
+	for _, suffix := range cosignSuffixes {
+		refs = append(refs, fmt.Sprintf("%v:sha256-%v%v", repo, hex, suffix))
+	}
+	return refs
+}
+
+// CopySignatures copies every cosign signature, SBOM, and attestation tag
+// that exists for digest from srcRepo to dstRepo, preserving their
+// manifests and layers byte-for-byte so verification still succeeds against
+// the migrated copy. Suffixes with no matching tag in srcRepo are skipped.
+func CopySignatures(srcRepo, dstRepo, digest string, srcKeychain, dstKeychain authn.Keychain) error {
+	for _, srcRef := range SignatureRefs(srcRepo, digest) {
+		ref, err := name.ParseReference(srcRef)
+		if err != nil {
+			return fmt.Errorf("parsing signature reference %q: %w", srcRef, err)
+		}
+
+		if _, err := remote.Head(ref, remote.WithAuthFromKeychain(srcKeychain)); err != nil {
+			var terr *transport.Error
+			if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+				// No signature/SBOM/attestation of this kind for this digest.
+				continue
+			}
+			return fmt.Errorf("checking for %v: %w", srcRef, err)
+		}
+
+		dstRef := strings.Replace(srcRef, srcRepo+":", dstRepo+":", 1)
+		if err := Retag(srcRef, srcKeychain, dstKeychain, dstRef); err != nil {
+			return fmt.Errorf("copying %v: %w", srcRef, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+// digestForSignatureTests is an arbitrary well-formed digest; its value
+// doesn't matter since the test server responds based on URL suffix alone.
+const digestForSignatureTests = "sha256:" + "a000000000000000000000000000000000000000000000000000000000000000"
+
+// newSignatureProbeServer returns a server that answers HEAD/GET requests
+// against cosign sibling tags with statusForSig for ".sig" tags and 404 for
+// every other suffix, so tests can control exactly one suffix's response.
+func newSignatureProbeServer(t *testing.T, statusForSig int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, ".sig"):
+			w.WriteHeader(statusForSig)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// TestCopySignaturesPropagatesNon404Errors guards against a prior
+// regression where CopySignatures treated any error from remote.Head
+// (network blips, auth failures, rate limiting) identically to "this
+// signature tag doesn't exist" and silently skipped it, risking a real
+// cosign signature being dropped with no indication it happened.
+func TestCopySignaturesPropagatesNon404Errors(t *testing.T) {
+	srv := newSignatureProbeServer(t, http.StatusForbidden)
+	defer srv.Close()
+
+	repo := srv.Listener.Addr().String() + "/repo"
+	err := CopySignatures(repo, repo, digestForSignatureTests, authn.Anonymous, authn.Anonymous)
+	if err == nil {
+		t.Fatal("CopySignatures returned nil error for a 403 probing the .sig tag, want a propagated error")
+	}
+}
+
+// TestCopySignaturesSkipsGenuine404 confirms the fix didn't overcorrect:
+// a real "no signature of this kind" 404 is still treated as nothing to
+// copy, not an error.
+func TestCopySignaturesSkipsGenuine404(t *testing.T) {
+	srv := newSignatureProbeServer(t, http.StatusNotFound)
+	defer srv.Close()
+
+	repo := srv.Listener.Addr().String() + "/repo"
+	if err := CopySignatures(repo, repo, digestForSignatureTests, authn.Anonymous, authn.Anonymous); err != nil {
+		t.Fatalf("CopySignatures with no signature tags present: %v", err)
+	}
+}
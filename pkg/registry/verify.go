@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/fulcioroots"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/signature"
+	rekor "github.com/sigstore/rekor/pkg/client"
+)
+
+// VerifyOptions configures cosign verification of an image before it is
+// copied to its destination.
+type VerifyOptions struct {
+	// PublicKeyPath, if set, verifies against a local or KMS-backed public
+	// key instead of keyless verification.
+	PublicKeyPath string
+	// Identities constrains keyless (Fulcio certificate + Rekor transparency
+	// log) verification to the given certificate identities. Ignored when
+	// PublicKeyPath is set.
+	Identities []cosign.Identity
+}
+
+// Verify checks that ref carries at least one valid cosign signature,
+// returning an error (intended to abort the migration) if verification
+// fails.
+func Verify(ctx context.Context, ref string, keychain authn.Keychain, opts VerifyOptions) error {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return fmt.Errorf("parsing reference %q: %w", ref, err)
+	}
+
+	co := &cosign.CheckOpts{
+		RegistryClientOpts: []ociremote.Option{ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(keychain))},
+	}
+
+	if opts.PublicKeyPath != "" {
+		verifier, err := signature.PublicKeyFromKeyRef(ctx, opts.PublicKeyPath)
+		if err != nil {
+			return fmt.Errorf("loading public key %q: %w", opts.PublicKeyPath, err)
+		}
+		co.SigVerifier = verifier
+	} else {
+		co.Identities = opts.Identities
+		if co.RekorClient, err = rekor.GetRekorClient(cosign.DefaultRekorURL); err != nil {
+			return fmt.Errorf("creating rekor client: %w", err)
+		}
+		if co.RootCerts, err = fulcioroots.Get(); err != nil {
+			return fmt.Errorf("loading fulcio roots: %w", err)
+		}
+		if co.IntermediateCerts, err = fulcioroots.GetIntermediates(); err != nil {
+			return fmt.Errorf("loading fulcio intermediate certs: %w", err)
+		}
+	}
+
+	if _, _, err := cosign.VerifyImageSignatures(ctx, r, co); err != nil {
+		return fmt.Errorf("verifying signature for %v: %w", r, err)
+	}
+	return nil
+}